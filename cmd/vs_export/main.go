@@ -0,0 +1,254 @@
+// Command vs_export导出Visual Studio解决方案为compile_commands.json，
+// 并提供若干辅助子命令用于排查解决方案本身的问题
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yaxsss/vs_export/sln"
+)
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "vs_export",
+		Short: "将Visual Studio解决方案导出为compile_commands.json",
+	}
+
+	root.AddCommand(newExportCmd())
+	root.AddCommand(newListCmd())
+	root.AddCommand(newValidateCmd())
+
+	return root
+}
+
+// compileCommandEntry是compile_commands.json里单条记录的JSON形状，
+// 独立于sln.CompileCommand，避免CLI和库之间耦合JSON标签
+type compileCommandEntry struct {
+	Directory string `json:"directory"`
+	File      string `json:"file"`
+	Command   string `json:"command"`
+}
+
+func newExportCmd() *cobra.Command {
+	var (
+		slnPath  string
+		config   string
+		platform string
+		out      string
+		projects []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "导出compile_commands.json等格式",
+	}
+
+	compileCommands := &cobra.Command{
+		Use:   "compile-commands",
+		Short: "导出compile_commands.json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := sln.NewSln(slnPath)
+			if err != nil {
+				return err
+			}
+
+			filterProjects(&s, projects)
+
+			cmds, err := s.CompileCommandsJson(config, platform)
+			if err != nil {
+				return err
+			}
+
+			entries := make([]compileCommandEntry, 0, len(cmds))
+			for _, c := range cmds {
+				entries = append(entries, compileCommandEntry{
+					Directory: c.Dir,
+					File:      c.File,
+					Command:   c.Cmd,
+				})
+			}
+
+			b, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			return os.WriteFile(out, b, 0644)
+		},
+	}
+	compileCommands.Flags().StringVar(&slnPath, "sln", "", "解决方案(.sln)文件路径")
+	compileCommands.Flags().StringVar(&config, "config", "Debug", "解决方案Configuration，如Debug")
+	compileCommands.Flags().StringVar(&platform, "platform", "x64", "解决方案Platform，如x64")
+	compileCommands.Flags().StringVar(&out, "out", "compile_commands.json", "输出文件路径")
+	compileCommands.Flags().StringArrayVar(&projects, "project", nil, "只导出指定名称的项目，可重复传入；不传则导出全部项目")
+	compileCommands.MarkFlagRequired("sln")
+
+	cmd.AddCommand(compileCommands)
+
+	return cmd
+}
+
+func newListCmd() *cobra.Command {
+	var slnPath string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "查看解决方案中的项目或Configuration",
+	}
+
+	projects := &cobra.Command{
+		Use:   "projects",
+		Short: "列出解决方案中的项目，包含GUID、源文件数量和各Configuration下的解析结果",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := sln.NewSln(slnPath)
+			if err != nil {
+				return err
+			}
+
+			for i := range s.ProjectList {
+				pro := &s.ProjectList[i]
+				fmt.Printf("%s\n", pro.ProjectPath)
+				fmt.Printf("  guid: %s\n", guidForProject(&s, pro))
+				fmt.Printf("  sources: %d\n", len(pro.FindSourceFiles()))
+				for _, sc := range s.SolutionConfigurations() {
+					projectConfig, projectPlatform, err := s.GetProjectConfigByProject(pro, sc.Configuration, sc.Platform)
+					if err != nil {
+						fmt.Printf("  %s -> 错误: %v\n", sc, err)
+						continue
+					}
+					fmt.Printf("  %s -> %s|%s\n", sc, projectConfig, projectPlatform)
+				}
+			}
+
+			return nil
+		},
+	}
+	projects.Flags().StringVar(&slnPath, "sln", "", "解决方案(.sln)文件路径")
+	projects.MarkFlagRequired("sln")
+
+	configurations := &cobra.Command{
+		Use:   "configurations",
+		Short: "列出解决方案支持的Configuration|Platform组合及各项目的映射",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := sln.NewSln(slnPath)
+			if err != nil {
+				return err
+			}
+
+			for _, sc := range s.SolutionConfigurations() {
+				fmt.Println(sc.String())
+				for i := range s.ProjectList {
+					pro := &s.ProjectList[i]
+					projectConfig, projectPlatform, err := s.GetProjectConfigByProject(pro, sc.Configuration, sc.Platform)
+					if err != nil {
+						continue
+					}
+					fmt.Printf("  %s -> %s|%s\n", pro.ProjectPath, projectConfig, projectPlatform)
+				}
+			}
+
+			return nil
+		},
+	}
+	configurations.Flags().StringVar(&slnPath, "sln", "", "解决方案(.sln)文件路径")
+	configurations.MarkFlagRequired("sln")
+
+	cmd.AddCommand(projects)
+	cmd.AddCommand(configurations)
+
+	return cmd
+}
+
+func newValidateCmd() *cobra.Command {
+	var slnPath string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "检查解决方案：缺失的项目文件、孤立的Configuration映射、未解析的宏",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := sln.NewSln(slnPath)
+			if err != nil {
+				return err
+			}
+
+			report := s.Validate()
+
+			for _, p := range report.MissingProjectFiles {
+				fmt.Printf("缺失项目文件: %s\n", p)
+			}
+			for _, m := range report.OrphanedMappings {
+				fmt.Printf("孤立的Configuration映射: %s\n", m)
+			}
+			for _, u := range report.UnresolvedMacros {
+				fmt.Printf("未解析的宏引用: %s\n", u)
+			}
+			for _, c := range report.ProjectConfigErrors {
+				fmt.Printf("项目Configuration错误: %s\n", c)
+			}
+
+			if !report.OK() {
+				os.Exit(1)
+			}
+			fmt.Println("未发现问题")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&slnPath, "sln", "", "解决方案(.sln)文件路径")
+	cmd.MarkFlagRequired("sln")
+
+	return cmd
+}
+
+// projectName返回项目文件名去掉扩展名的部分，与sln.NewMacroExpander里$(ProjectName)的算法保持一致
+func projectName(pro *sln.Project) string {
+	base := filepath.Base(pro.ProjectPath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// filterProjects将s.ProjectList裁剪为仅包含名称在names中的项目；names为空时不做任何过滤
+func filterProjects(s *sln.Sln, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+
+	filtered := s.ProjectList[:0]
+	for _, pro := range s.ProjectList {
+		if want[projectName(&pro)] {
+			filtered = append(filtered, pro)
+		}
+	}
+	s.ProjectList = filtered
+}
+
+// projectRelPath在ProjectGUIDs中反查pro对应的相对路径
+func projectRelPath(s *sln.Sln, pro *sln.Project) string {
+	for path := range s.ProjectGUIDs {
+		if filepath.Join(s.SolutionDir, path) == pro.ProjectPath {
+			return path
+		}
+	}
+	return ""
+}
+
+// guidForProject在ProjectGUIDs中反查pro对应的GUID
+func guidForProject(s *sln.Sln, pro *sln.Project) string {
+	return s.ProjectGUIDs[projectRelPath(s, pro)]
+}
@@ -0,0 +1,99 @@
+package sln
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// generateSyntheticProjects在dir下生成count个结构相同的.vcxproj文件，
+// 用于衡量loadProjects在大型解决方案上的表现，不依赖真实的VS工程
+func generateSyntheticProjects(tb testing.TB, dir string, count int) []string {
+	tb.Helper()
+
+	paths := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("project%d.vcxproj", i)
+		content := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<Project>
+  <ItemGroup>
+    <ClCompile Include="src%d_a.cpp" />
+    <ClCompile Include="src%d_b.cpp" />
+  </ItemGroup>
+  <ItemDefinitionGroup Condition="'$(Configuration)|$(Platform)'=='Debug|x64'">
+    <ClCompile>
+      <AdditionalIncludeDirectories>$(ProjectDir)include;%%(AdditionalIncludeDirectories)</AdditionalIncludeDirectories>
+      <PreprocessorDefinitions>FOO=%d;%%(PreprocessorDefinitions)</PreprocessorDefinitions>
+    </ClCompile>
+  </ItemDefinitionGroup>
+</Project>
+`, i, i, i)
+
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		paths = append(paths, name)
+	}
+
+	return paths
+}
+
+// BenchmarkLoadProjects比较loadProjects在500个合成项目上串行（Concurrency:1）
+// 与并行（Concurrency:0，即默认runtime.NumCPU()）两种方式的耗时，
+// 用于衡量chunk0-5引入并发加载带来的加速效果
+func BenchmarkLoadProjects(b *testing.B) {
+	const projectCount = 500
+
+	dir := b.TempDir()
+	paths := generateSyntheticProjects(b, dir, projectCount)
+
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := loadProjects(dir, paths, LoadOptions{Concurrency: 1}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := loadProjects(dir, paths, LoadOptions{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestLoadProjects_SerialAndParallelAgree校验并发加载在500个合成项目上的结果
+// 与串行加载完全一致（按路径顺序逐一比较ProjectPath和源文件列表），
+// 确保BenchmarkLoadProjects展示的加速不是以牺牲正确性换来的
+func TestLoadProjects_SerialAndParallelAgree(t *testing.T) {
+	const projectCount = 500
+
+	dir := t.TempDir()
+	paths := generateSyntheticProjects(t, dir, projectCount)
+
+	serial, err := loadProjects(dir, paths, LoadOptions{Concurrency: 1})
+	if err != nil {
+		t.Fatalf("串行加载返回错误: %v", err)
+	}
+
+	parallel, err := loadProjects(dir, paths, LoadOptions{})
+	if err != nil {
+		t.Fatalf("并行加载返回错误: %v", err)
+	}
+
+	if len(serial) != len(parallel) {
+		t.Fatalf("项目数量不一致: serial=%d parallel=%d", len(serial), len(parallel))
+	}
+
+	for i := range serial {
+		if serial[i].ProjectPath != parallel[i].ProjectPath {
+			t.Fatalf("第%d项ProjectPath不一致: serial=%s parallel=%s", i, serial[i].ProjectPath, parallel[i].ProjectPath)
+		}
+		if fmt.Sprint(serial[i].FindSourceFiles()) != fmt.Sprint(parallel[i].FindSourceFiles()) {
+			t.Fatalf("第%d项源文件列表不一致: serial=%v parallel=%v", i, serial[i].FindSourceFiles(), parallel[i].FindSourceFiles())
+		}
+	}
+}
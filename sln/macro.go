@@ -0,0 +1,148 @@
+package sln
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// macroRefRe 匹配形如$(Name)的MSBuild属性引用
+var macroRefRe = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// ErrMacroCycle 包装在Expand/ExpandList返回的循环引用错误里，
+// 供Validate用errors.Is区分"宏循环引用"和FindConfig的其他错误（如Configuration不存在）
+var ErrMacroCycle = errors.New("宏循环引用")
+
+// MacroExpander 按项目展开标准的MSBuild保留属性（$(ProjectDir)、$(Configuration)等），
+// 替换在解决方案/项目文件中随处可见的宏引用。属性之间允许互相引用，
+// 展开过程是递归的，并会检测循环引用。未知的宏名会回退到环境变量，
+// 两者都找不到时原样保留，交由调用方决定如何处理。
+type MacroExpander struct {
+	props map[string]string
+}
+
+// NewMacroExpander 为pro在(configuration, platform)下构造一组标准MSBuild属性。
+// 这套属性同时供CompileCommandsJson和Project.FindConfig使用，
+// 保证宏在整条导出流水线上的展开结果一致。
+func NewMacroExpander(s *Sln, pro *Project, configuration, platform string) *MacroExpander {
+	projectFileName := filepath.Base(pro.ProjectPath)
+	projectName := strings.TrimSuffix(projectFileName, filepath.Ext(projectFileName))
+
+	props := map[string]string{
+		"ProjectDir":        withTrailingSlash(pro.ProjectDir),
+		"ProjectName":       projectName,
+		"ProjectFileName":   projectFileName,
+		"ProjectPath":       pro.ProjectPath,
+		"SolutionDir":       withTrailingSlash(s.SolutionDir),
+		"SolutionName":      s.SolutionName,
+		"Configuration":     configuration,
+		"Platform":          platform,
+		"PlatformShortName": platformShortName(platform),
+		"TargetName":        projectName,
+		// OutDir/IntDir先填VS的默认布局，如果pro在configuration|platform下
+		// 显式声明了<OutDir>/<IntDir>，下面会用vcxproj里的真实值覆盖掉默认值
+		"OutDir": `$(ProjectDir)$(Platform)\$(Configuration)\`,
+		"IntDir": `$(ProjectDir)$(Platform)\$(Configuration)\obj\`,
+	}
+
+	if outDir, intDir := pro.FindOutputDirs(configuration + "|" + platform); outDir != "" || intDir != "" {
+		if outDir != "" {
+			props["OutDir"] = outDir
+		}
+		if intDir != "" {
+			props["IntDir"] = intDir
+		}
+	}
+
+	return &MacroExpander{props: props}
+}
+
+// Set 覆盖或新增一个属性，供调用方按需注入标准属性之外的值
+func (e *MacroExpander) Set(name, value string) {
+	e.props[name] = value
+}
+
+// Expand 递归展开s中出现的全部$(Name)引用
+func (e *MacroExpander) Expand(s string) (string, error) {
+	return e.expand(s, nil)
+}
+
+func (e *MacroExpander) expand(s string, stack []string) (string, error) {
+	matches := macroRefRe.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		name := s[m[2]:m[3]]
+		sb.WriteString(s[last:m[0]])
+		last = m[1]
+
+		for _, seen := range stack {
+			if seen == name {
+				return "", fmt.Errorf("%w: %s -> %s", ErrMacroCycle, strings.Join(stack, " -> "), name)
+			}
+		}
+
+		val, ok := e.props[name]
+		if !ok {
+			val, ok = os.LookupEnv(name)
+		}
+		if !ok {
+			// 未知的宏，原样保留
+			sb.WriteString(s[m[0]:m[1]])
+			continue
+		}
+
+		expanded, err := e.expand(val, append(stack, name))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(expanded)
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), nil
+}
+
+// ExpandList 对以分号分隔的列表（AdditionalIncludeDirectories等vcxproj里常见的形式）
+// 逐项展开，保留原有的分号分隔结构
+func (e *MacroExpander) ExpandList(sepedString string) (string, error) {
+	items := strings.Split(sepedString, ";")
+	for i, item := range items {
+		expanded, err := e.Expand(item)
+		if err != nil {
+			return "", err
+		}
+		items[i] = expanded
+	}
+	return strings.Join(items, ";"), nil
+}
+
+func withTrailingSlash(dir string) string {
+	if dir == "" {
+		return dir
+	}
+	if strings.HasSuffix(dir, "/") || strings.HasSuffix(dir, "\\") {
+		return dir
+	}
+	return dir + string(filepath.Separator)
+}
+
+// platformShortName 返回$(PlatformShortName)对应的值，规则与VS保持一致
+func platformShortName(platform string) string {
+	switch strings.ToLower(platform) {
+	case "win32":
+		return "x86"
+	case "x64":
+		return "x64"
+	case "arm64":
+		return "ARM64"
+	default:
+		return platform
+	}
+}
@@ -0,0 +1,133 @@
+package sln
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func newTestMacroExpander() *MacroExpander {
+	s := &Sln{SolutionDir: "/sol", SolutionName: "test"}
+	pro := &Project{ProjectPath: "/sol/proj/proj.vcxproj", ProjectDir: "/sol/proj"}
+	return NewMacroExpander(s, pro, "Debug", "x64")
+}
+
+// TestMacroExpander_CycleDetection覆盖了请求里明确要求的循环引用检测：
+// A展开引用B，B又展开引用A，Expand应返回包装了ErrMacroCycle的错误，而不是死循环或栈溢出
+func TestMacroExpander_CycleDetection(t *testing.T) {
+	e := newTestMacroExpander()
+	e.Set("A", "$(B)")
+	e.Set("B", "$(A)")
+
+	_, err := e.Expand("$(A)")
+	if err == nil {
+		t.Fatal("期望返回循环引用错误，实际没有返回错误")
+	}
+	if !errors.Is(err, ErrMacroCycle) {
+		t.Fatalf("期望错误包装ErrMacroCycle，实际err = %v", err)
+	}
+}
+
+// TestMacroExpander_EnvFallback覆盖了请求里明确要求的环境变量回退：
+// 属性表里没有的宏名会尝试从环境变量读取
+func TestMacroExpander_EnvFallback(t *testing.T) {
+	t.Setenv("VS_EXPORT_TEST_MACRO", "fromEnv")
+
+	e := newTestMacroExpander()
+	got, err := e.Expand("$(VS_EXPORT_TEST_MACRO)")
+	if err != nil {
+		t.Fatalf("Expand返回错误: %v", err)
+	}
+	if got != "fromEnv" {
+		t.Fatalf("got = %q, 期望从环境变量展开为fromEnv", got)
+	}
+}
+
+// TestMacroExpander_UnknownMacroPassthrough覆盖了既不在属性表也不在环境变量里的宏：
+// 应原样保留$(...)，而不是报错或被清空
+func TestMacroExpander_UnknownMacroPassthrough(t *testing.T) {
+	if _, ok := os.LookupEnv("VsExportTotallyUnknownMacro"); ok {
+		t.Fatal("测试前置条件被破坏：环境变量不应该存在")
+	}
+
+	e := newTestMacroExpander()
+	got, err := e.Expand("prefix-$(VsExportTotallyUnknownMacro)-suffix")
+	if err != nil {
+		t.Fatalf("Expand返回错误: %v", err)
+	}
+	if got != "prefix-$(VsExportTotallyUnknownMacro)-suffix" {
+		t.Fatalf("got = %q, 期望未知宏原样保留", got)
+	}
+}
+
+// TestMacroExpander_ReservedProperties覆盖标准保留属性的展开，包括vcxproj未声明
+// OutDir/IntDir时回退到的VS默认布局
+func TestMacroExpander_ReservedProperties(t *testing.T) {
+	e := newTestMacroExpander()
+
+	cases := map[string]string{
+		"$(ProjectName)":       "proj",
+		"$(Configuration)":     "Debug",
+		"$(Platform)":          "x64",
+		"$(PlatformShortName)": "x64",
+		"$(OutDir)":            `/sol/proj/x64\Debug\`,
+		"$(IntDir)":            `/sol/proj/x64\Debug\obj\`,
+	}
+	for input, want := range cases {
+		got, err := e.Expand(input)
+		if err != nil {
+			t.Fatalf("Expand(%q)返回错误: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("Expand(%q) = %q, 期望 %q", input, got, want)
+		}
+	}
+}
+
+// TestMacroExpander_PlatformShortNameWin32覆盖Win32的$(PlatformShortName)应
+// 展开为x86而不是原样保留Win32（与真实VS/MSBuild的命名规则一致）
+func TestMacroExpander_PlatformShortNameWin32(t *testing.T) {
+	s := &Sln{SolutionDir: "/sol", SolutionName: "test"}
+	pro := &Project{ProjectPath: "/sol/proj/proj.vcxproj", ProjectDir: "/sol/proj"}
+	e := NewMacroExpander(s, pro, "Debug", "Win32")
+
+	got, err := e.Expand("$(PlatformShortName)")
+	if err != nil {
+		t.Fatalf("Expand返回错误: %v", err)
+	}
+	if got != "x86" {
+		t.Fatalf("got = %q, 期望Win32的PlatformShortName展开为x86", got)
+	}
+}
+
+// TestMacroExpander_OutDirFromVcxproj覆盖NewMacroExpander用vcxproj里针对
+// configuration|platform显式声明的OutDir/IntDir覆盖默认布局的场景
+func TestMacroExpander_OutDirFromVcxproj(t *testing.T) {
+	s := &Sln{SolutionDir: "/sol", SolutionName: "test"}
+	pro := &Project{ProjectPath: "/sol/proj/proj.vcxproj", ProjectDir: "/sol/proj"}
+	pro.raw.PropertyGroups = []xmlPropertyGroup{
+		{
+			Condition: "'$(Configuration)|$(Platform)'=='Debug|x64'",
+			OutDir:    `bin\Debug\`,
+			IntDir:    `obj\Debug\`,
+		},
+	}
+
+	e := NewMacroExpander(s, pro, "Debug", "x64")
+
+	gotOut, err := e.Expand("$(OutDir)")
+	if err != nil {
+		t.Fatalf("Expand($(OutDir))返回错误: %v", err)
+	}
+	if gotOut != `bin\Debug\` {
+		t.Fatalf("OutDir = %q, 期望使用vcxproj里显式声明的值", gotOut)
+	}
+
+	gotInt, err := e.Expand("$(IntDir)")
+	if err != nil {
+		t.Fatalf("Expand($(IntDir))返回错误: %v", err)
+	}
+	if gotInt != `obj\Debug\` {
+		t.Fatalf("IntDir = %q, 期望使用vcxproj里显式声明的值", gotInt)
+	}
+}
@@ -24,6 +24,7 @@ type vcxprojXML struct {
 	XMLName              xml.Name                 `xml:"Project"`
 	ItemGroups           []xmlItemGroup           `xml:"ItemGroup"`
 	ItemDefinitionGroups []xmlItemDefinitionGroup `xml:"ItemDefinitionGroup"`
+	PropertyGroups       []xmlPropertyGroup       `xml:"PropertyGroup"`
 }
 
 type xmlItemGroup struct {
@@ -47,6 +48,15 @@ type xmlItemDefinitionGroup struct {
 type xmlClCompileOpts struct {
 	AdditionalIncludeDirectories string `xml:"AdditionalIncludeDirectories"`
 	PreprocessorDefinitions      string `xml:"PreprocessorDefinitions"`
+	AdditionalOptions            string `xml:"AdditionalOptions"`
+}
+
+// xmlPropertyGroup对应vcxproj里按Configuration|Platform声明输出目录的
+// <PropertyGroup Condition="...">，与xmlItemDefinitionGroup是两类不同的节点
+type xmlPropertyGroup struct {
+	Condition string `xml:"Condition,attr"`
+	OutDir    string `xml:"OutDir"`
+	IntDir    string `xml:"IntDir"`
 }
 
 // NewProject 解析path指向的.vcxproj文件
@@ -104,17 +114,48 @@ func (p *Project) FindProjectReferences() []string {
 	return refs
 }
 
-// FindConfig 返回configPlatform（形如"Debug|x64"）对应的AdditionalIncludeDirectories和
-// PreprocessorDefinitions原始值（分号分隔，可能还包含未展开的MSBuild宏）
-func (p *Project) FindConfig(configPlatform string) (inc string, def string, err error) {
+// FindConfig 返回configPlatform（形如"Debug|x64"）对应的AdditionalIncludeDirectories、
+// PreprocessorDefinitions和AdditionalOptions，并用macros就地展开三者里出现的MSBuild宏引用，
+// 保证不论调用方是CompileCommandsJson还是Validate，宏的展开规则都完全一致
+func (p *Project) FindConfig(configPlatform string, macros *MacroExpander) (inc string, def string, opts string, err error) {
 	for _, group := range p.raw.ItemDefinitionGroups {
 		if !conditionMatchesConfig(group.Condition, configPlatform) {
 			continue
 		}
-		return group.ClCompile.AdditionalIncludeDirectories, group.ClCompile.PreprocessorDefinitions, nil
+
+		inc, err = macros.ExpandList(group.ClCompile.AdditionalIncludeDirectories)
+		if err != nil {
+			return "", "", "", err
+		}
+		def, err = macros.ExpandList(group.ClCompile.PreprocessorDefinitions)
+		if err != nil {
+			return "", "", "", err
+		}
+		opts, err = macros.Expand(group.ClCompile.AdditionalOptions)
+		if err != nil {
+			return "", "", "", err
+		}
+		return inc, def, opts, nil
 	}
 
-	return "", "", fmt.Errorf("项目 %s 中未找到Configuration %s 对应的设置", p.ProjectPath, configPlatform)
+	return "", "", "", fmt.Errorf("项目 %s 中未找到Configuration %s 对应的设置", p.ProjectPath, configPlatform)
+}
+
+// FindOutputDirs 返回vcxproj里针对configPlatform显式声明的OutDir/IntDir原始值（未展开宏）。
+// 项目没有声明时对应返回值为空字符串，调用方（NewMacroExpander）应保留$(OutDir)/$(IntDir)的默认值
+func (p *Project) FindOutputDirs(configPlatform string) (outDir string, intDir string) {
+	for _, group := range p.raw.PropertyGroups {
+		if !conditionMatchesConfig(group.Condition, configPlatform) {
+			continue
+		}
+		if group.OutDir != "" {
+			outDir = group.OutDir
+		}
+		if group.IntDir != "" {
+			intDir = group.IntDir
+		}
+	}
+	return outDir, intDir
 }
 
 // conditionMatchesConfig判断vcxproj里的Condition属性（形如
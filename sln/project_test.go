@@ -0,0 +1,58 @@
+package sln
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testVcxproj = `<?xml version="1.0" encoding="utf-8"?>
+<Project>
+  <ItemGroup>
+    <ClCompile Include="main.cpp" />
+  </ItemGroup>
+  <ItemDefinitionGroup Condition="'$(Configuration)|$(Platform)'=='Debug|x64'">
+    <ClCompile>
+      <AdditionalIncludeDirectories>$(ProjectDir)include;%(AdditionalIncludeDirectories)</AdditionalIncludeDirectories>
+      <PreprocessorDefinitions>FOO=$(Configuration);%(PreprocessorDefinitions)</PreprocessorDefinitions>
+      <AdditionalOptions>/std:c++17 %(AdditionalOptions)</AdditionalOptions>
+    </ClCompile>
+  </ItemDefinitionGroup>
+</Project>
+`
+
+// TestProjectFindConfig_ExpandsMacrosAndAdditionalOptions覆盖了FindConfig自己展开
+// include/宏定义/AdditionalOptions里MSBuild宏引用的行为，而不是依赖调用方事后
+// 再跑一遍macros.ExpandList——否则AdditionalOptions永远不会被展开，也不会出现在
+// 最终拼出的命令行里
+func TestProjectFindConfig_ExpandsMacrosAndAdditionalOptions(t *testing.T) {
+	dir := t.TempDir()
+	vcxprojPath := filepath.Join(dir, "foo.vcxproj")
+	if err := os.WriteFile(vcxprojPath, []byte(testVcxproj), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pro, err := NewProject(vcxprojPath)
+	if err != nil {
+		t.Fatalf("NewProject返回错误: %v", err)
+	}
+
+	s := Sln{SolutionDir: dir, SolutionName: "test"}
+	macros := NewMacroExpander(&s, &pro, "Debug", "x64")
+
+	inc, def, opts, err := pro.FindConfig("Debug|x64", macros)
+	if err != nil {
+		t.Fatalf("FindConfig返回错误: %v", err)
+	}
+
+	wantInc := pro.ProjectDir + string(filepath.Separator) + "include;%(AdditionalIncludeDirectories)"
+	if inc != wantInc {
+		t.Errorf("inc = %q, 期望 %q", inc, wantInc)
+	}
+	if def != "FOO=Debug;%(PreprocessorDefinitions)" {
+		t.Errorf("def = %q, 期望FOO=Debug被展开", def)
+	}
+	if opts != "/std:c++17 %(AdditionalOptions)" {
+		t.Errorf("opts = %q, AdditionalOptions未被透传", opts)
+	}
+}
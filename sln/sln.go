@@ -7,28 +7,79 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // 添加新的结构体来管理Configuration映射
+// .sln中的每一行形如{GUID}.Debug|x64.ActiveCfg = Debug|Win32，
+// 解决方案侧和项目侧的Configuration、Platform都需要单独保留，
+// 因为项目的Platform可能与解决方案的Platform不同（例如x64解决方案下某个项目只构建Win32）
 type ProjectConfigMapping struct {
-	ProjectGUID    string
-	SolutionConfig string
-	ProjectConfig  string
-	ShouldBuild    bool
+	ProjectGUID           string
+	SolutionConfiguration string
+	SolutionPlatform      string
+	ProjectConfiguration  string
+	ProjectPlatform       string
+	ShouldBuild           bool
+}
+
+// SolutionConfig 表示解决方案支持的一个Configuration|Platform组合，
+// 来自.sln文件中的GlobalSection(SolutionConfigurationPlatforms)
+type SolutionConfig struct {
+	Configuration string
+	Platform      string
+}
+
+// String 返回"Configuration|Platform"形式，与.sln文件中的表示一致
+func (c SolutionConfig) String() string {
+	return c.Configuration + "|" + c.Platform
 }
 
 type Sln struct {
-	SolutionDir    string
-	ProjectList    []Project
-	ProjectGUIDs   map[string]string      // ProjectPath -> GUID的映射
-	ConfigMappings []ProjectConfigMapping // Configuration映射关系
+	SolutionDir     string
+	SolutionName    string // .sln文件名（不含扩展名），用于$(SolutionName)宏
+	ProjectList     []Project
+	ProjectGUIDs    map[string]string      // ProjectPath -> GUID的映射
+	ConfigMappings  []ProjectConfigMapping // Configuration映射关系
+	Dependencies    map[string][]string    // ProjectGUID -> 它依赖的ProjectGUID列表
+	solutionConfigs []SolutionConfig       // 解决方案支持的Configuration|Platform列表
+	cache           *parseCache            // FindConfig结果缓存，按项目+Configuration+Platform去重
+}
+
+// LoadOptions控制NewSlnWithOptions加载项目文件时的并发度和进度回调
+type LoadOptions struct {
+	Concurrency int                   // 并发加载项目的worker数量，<=0时使用runtime.NumCPU()
+	Progress    func(done, total int) // 每完成一个项目加载都会被调用一次，可用于展示进度条
+}
+
+// parseCache缓存FindConfig的结果，键为"ProjectPath|Configuration|Platform"，
+// 在大型解决方案上避免同一项目配置被重复解析
+type parseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedConfig
+}
+
+type cachedConfig struct {
+	inc  string
+	def  string
+	opts string
 }
 
 func NewSln(path string) (Sln, error) {
+	return NewSlnWithOptions(path, LoadOptions{})
+}
+
+// NewSlnWithOptions与NewSln相同，但允许调用方控制项目加载的并发度并观察进度，
+// 这在项目数量较多的解决方案上能显著缩短加载时间
+func NewSlnWithOptions(path string, opts LoadOptions) (Sln, error) {
 	var sln Sln
 	var err error
 
+	sln.SolutionName = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
 	sln.SolutionDir, err = filepath.Abs(path)
 	sln.SolutionDir = filepath.Dir(sln.SolutionDir)
 	if err != nil {
@@ -37,23 +88,80 @@ func NewSln(path string) (Sln, error) {
 
 	// 初始化映射
 	sln.ProjectGUIDs = make(map[string]string)
+	sln.cache = &parseCache{entries: make(map[string]cachedConfig)}
 
-	// 解析项目文件和Configuration映射
+	// 解析项目文件、Configuration映射和项目依赖关系
 	err = sln.parseSolutionFile(path)
 	if err != nil {
 		return sln, err
 	}
 
-	// 加载项目文件
+	projectPaths := make([]string, 0, len(sln.ProjectGUIDs))
 	for projectPath := range sln.ProjectGUIDs {
-		pro, err := NewProject(filepath.Join(sln.SolutionDir, projectPath))
+		projectPaths = append(projectPaths, projectPath)
+	}
+
+	// 并发加载项目文件，worker数量由opts.Concurrency控制
+	sln.ProjectList, err = loadProjects(sln.SolutionDir, projectPaths, opts)
+	if err != nil {
+		return sln, err
+	}
+
+	// 补充vcxproj里<ProjectReference>隐式声明的依赖，它们不一定出现在.sln的
+	// ProjectSection(ProjectDependencies)里
+	sln.mergeProjectReferenceDependencies()
+
+	return sln, nil
+}
+
+// loadProjects用一个有界worker池并发调用NewProject，结果按projectPaths的顺序聚合，
+// 使得加载结果与单线程版本一样是确定性的
+func loadProjects(solutionDir string, projectPaths []string, opts LoadOptions) ([]Project, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	if concurrency > len(projectPaths) {
+		concurrency = len(projectPaths)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]Project, len(projectPaths))
+	errs := make([]error, len(projectPaths))
+	var done int32
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				pro, err := NewProject(filepath.Join(solutionDir, projectPaths[i]))
+				results[i] = pro
+				errs[i] = err
+				if opts.Progress != nil {
+					opts.Progress(int(atomic.AddInt32(&done, 1)), len(projectPaths))
+				}
+			}
+		}()
+	}
+
+	for i := range projectPaths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
 		if err != nil {
-			return sln, err
+			return nil, err
 		}
-		sln.ProjectList = append(sln.ProjectList, pro)
 	}
 
-	return sln, nil
+	return results, nil
 }
 
 // 解析.sln文件，提取项目和Configuration映射信息
@@ -83,9 +191,64 @@ func (sln *Sln) parseSolutionFile(path string) error {
 		return err
 	}
 
+	// 解析解决方案支持的Configuration|Platform列表
+	err = sln.parseSolutionConfigurations(content)
+	if err != nil {
+		return err
+	}
+
+	// 解析每个Project块内的ProjectSection(ProjectDependencies)，构建依赖关系图
+	err = sln.parseProjectDependencies(content)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// 解析GlobalSection(SolutionConfigurationPlatforms)部分，
+// 记录解决方案支持的Configuration|Platform组合
+func (sln *Sln) parseSolutionConfigurations(content string) error {
+	sectionStart := strings.Index(content, "GlobalSection(SolutionConfigurationPlatforms)")
+	if sectionStart == -1 {
+		return nil // 没有该节也是可以的，后续校验会被跳过
+	}
+
+	sectionEnd := strings.Index(content[sectionStart:], "EndGlobalSection")
+	if sectionEnd == -1 {
+		return errors.New("SolutionConfigurationPlatforms部分格式错误")
+	}
+
+	section := content[sectionStart : sectionStart+sectionEnd]
+
+	// 匹配形如：Debug|x64 = Debug|x64
+	re := regexp.MustCompile(`([A-Za-z0-9_ ]+)\|([A-Za-z0-9_ ]+)\s*=\s*[A-Za-z0-9_ ]+\|[A-Za-z0-9_ ]+`)
+	matches := re.FindAllStringSubmatch(section, -1)
+
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		if len(match) < 3 {
+			continue
+		}
+		cfg := SolutionConfig{
+			Configuration: strings.TrimSpace(match[1]),
+			Platform:      strings.TrimSpace(match[2]),
+		}
+		if seen[cfg.String()] {
+			continue
+		}
+		seen[cfg.String()] = true
+		sln.solutionConfigs = append(sln.solutionConfigs, cfg)
+	}
+
 	return nil
 }
 
+// SolutionConfigurations 返回解决方案支持的全部Configuration|Platform组合
+func (sln *Sln) SolutionConfigurations() []SolutionConfig {
+	return sln.solutionConfigs
+}
+
 // 解析项目引用部分
 func (sln *Sln) parseProjectReferences(content string) error {
 	// 匹配项目引用行：Project("{GUID}") = "ProjectName", "ProjectPath", "{ProjectGUID}"
@@ -107,6 +270,150 @@ func (sln *Sln) parseProjectReferences(content string) error {
 	return nil
 }
 
+// 解析每个Project(...)...EndProject块内的ProjectSection(ProjectDependencies)部分，
+// 记录显式声明的"先构建A再构建B"依赖关系
+func (sln *Sln) parseProjectDependencies(content string) error {
+	sln.Dependencies = make(map[string][]string)
+
+	// 注意：EndProject后面不能加\b以外的边界判断，因为EndProjectSection也以
+	// "EndProject"开头，非贪婪匹配会被它截断，导致ProjectSection(ProjectDependencies)
+	// 部分一直取不到、静默跳过
+	blockRe := regexp.MustCompile(`(?s)Project\("\{[^}]+\}"\)\s*=\s*"[^"]+",\s*"[^"]+",\s*"\{([^}]+)\}"(.*?)EndProject\b`)
+	blocks := blockRe.FindAllStringSubmatch(content, -1)
+
+	depRe := regexp.MustCompile(`\{([^}]+)\}\s*=\s*\{[^}]+\}`)
+
+	for _, block := range blocks {
+		if len(block) < 3 {
+			continue
+		}
+		ownerGUID := block[1]
+		body := block[2]
+
+		sectionStart := strings.Index(body, "ProjectSection(ProjectDependencies)")
+		if sectionStart == -1 {
+			continue
+		}
+		sectionEnd := strings.Index(body[sectionStart:], "EndProjectSection")
+		if sectionEnd == -1 {
+			continue
+		}
+		section := body[sectionStart : sectionStart+sectionEnd]
+
+		for _, dep := range depRe.FindAllStringSubmatch(section, -1) {
+			sln.Dependencies[ownerGUID] = append(sln.Dependencies[ownerGUID], dep[1])
+		}
+	}
+
+	return nil
+}
+
+// guidForProjectPath在ProjectGUIDs中反查absPath（项目文件的绝对路径）对应的GUID
+func (sln *Sln) guidForProjectPath(absPath string) (string, bool) {
+	for relPath, guid := range sln.ProjectGUIDs {
+		if filepath.Join(sln.SolutionDir, relPath) == absPath {
+			return guid, true
+		}
+	}
+	return "", false
+}
+
+// mergeProjectReferenceDependencies补充vcxproj里<ProjectReference>隐式声明的依赖关系到
+// sln.Dependencies，必须在ProjectList加载完成后调用
+func (sln *Sln) mergeProjectReferenceDependencies() {
+	for i := range sln.ProjectList {
+		pro := &sln.ProjectList[i]
+
+		ownerGUID, ok := sln.guidForProjectPath(pro.ProjectPath)
+		if !ok {
+			continue
+		}
+
+		for _, refPath := range pro.FindProjectReferences() {
+			absRef := refPath
+			if !filepath.IsAbs(absRef) {
+				absRef = filepath.Join(filepath.Dir(pro.ProjectPath), refPath)
+			}
+
+			depGUID, ok := sln.guidForProjectPath(absRef)
+			if !ok {
+				continue // 引用的项目不在解决方案中（极少见，但不应阻塞整体解析）
+			}
+
+			if !containsString(sln.Dependencies[ownerGUID], depGUID) {
+				sln.Dependencies[ownerGUID] = append(sln.Dependencies[ownerGUID], depGUID)
+			}
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildOrder 依据sln.Dependencies对ProjectList做拓扑排序，依赖项排在依赖它的项目之前，
+// 供增量索引等需要按构建顺序消费compile_commands.json的下游工具使用。
+// 存在循环依赖时返回错误
+func (sln *Sln) BuildOrder() ([]*Project, error) {
+	guidToProject := make(map[string]*Project, len(sln.ProjectList))
+	for i := range sln.ProjectList {
+		pro := &sln.ProjectList[i]
+		if guid, ok := sln.guidForProjectPath(pro.ProjectPath); ok {
+			guidToProject[guid] = pro
+		}
+	}
+
+	const (
+		white = 0
+		gray  = 1
+		black = 2
+	)
+	state := make(map[string]int, len(guidToProject))
+	var order []*Project
+
+	var visit func(guid string, path []string) error
+	visit = func(guid string, path []string) error {
+		switch state[guid] {
+		case black:
+			return nil
+		case gray:
+			return fmt.Errorf("项目依赖存在循环: %s -> %s", strings.Join(path, " -> "), guid)
+		}
+
+		state[guid] = gray
+		for _, dep := range sln.Dependencies[guid] {
+			if _, ok := guidToProject[dep]; !ok {
+				continue // 依赖指向解决方案中不存在/非vcxproj的项目，忽略
+			}
+			if err := visit(dep, append(path, guid)); err != nil {
+				return err
+			}
+		}
+		state[guid] = black
+		order = append(order, guidToProject[guid])
+		return nil
+	}
+
+	// 按ProjectList原有顺序访问，使得没有依赖关系差异的项目之间输出顺序保持稳定
+	for i := range sln.ProjectList {
+		pro := &sln.ProjectList[i]
+		guid, ok := sln.guidForProjectPath(pro.ProjectPath)
+		if !ok {
+			continue
+		}
+		if err := visit(guid, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
 // 解析Configuration映射部分
 func (sln *Sln) parseConfigurationMappings(content string) error {
 	// 查找ProjectConfigurationPlatforms部分
@@ -122,28 +429,31 @@ func (sln *Sln) parseConfigurationMappings(content string) error {
 
 	configSection := content[configSectionStart : configSectionStart+configSectionEnd]
 
-	// 匹配Configuration映射行：{GUID}.SolutionConfig.ActiveCfg = ProjectConfig
-	// 或：{GUID}.SolutionConfig.Build.0 = ProjectConfig
+	// 匹配Configuration映射行：{GUID}.SolutionConfig|SolutionPlatform.ActiveCfg = ProjectConfig|ProjectPlatform
+	// 或：{GUID}.SolutionConfig|SolutionPlatform.Build.0 = ProjectConfig|ProjectPlatform
 	re := regexp.MustCompile(`\{([^}]+)\}\.([^.]+)\.(?:ActiveCfg|Build\.0)\s*=\s*(.+?)(?:\r?\n|$)`)
 	matches := re.FindAllStringSubmatch(configSection, -1)
 
 	for _, match := range matches {
 		if len(match) >= 4 {
 			projectGUID := match[1]
-			solutionConfig := match[2]
-			projectConfig := strings.TrimSpace(match[3]) // 去掉前后空白字符
+			solutionConfiguration, solutionPlatform := splitConfigPlatform(match[2])
+			projectConfiguration, projectPlatform := splitConfigPlatform(strings.TrimSpace(match[3]))
 			shouldBuild := strings.Contains(match[0], "Build.0")
 
 			// 检查是否已存在相同的映射
 			found := false
 			for i, mapping := range sln.ConfigMappings {
-				if mapping.ProjectGUID == projectGUID && mapping.SolutionConfig == solutionConfig {
+				if mapping.ProjectGUID == projectGUID &&
+					mapping.SolutionConfiguration == solutionConfiguration &&
+					mapping.SolutionPlatform == solutionPlatform {
 					// 更新现有映射
 					if shouldBuild {
 						sln.ConfigMappings[i].ShouldBuild = true
 					}
-					if sln.ConfigMappings[i].ProjectConfig == "" {
-						sln.ConfigMappings[i].ProjectConfig = projectConfig
+					if sln.ConfigMappings[i].ProjectConfiguration == "" {
+						sln.ConfigMappings[i].ProjectConfiguration = projectConfiguration
+						sln.ConfigMappings[i].ProjectPlatform = projectPlatform
 					}
 					found = true
 					break
@@ -152,10 +462,12 @@ func (sln *Sln) parseConfigurationMappings(content string) error {
 
 			if !found {
 				sln.ConfigMappings = append(sln.ConfigMappings, ProjectConfigMapping{
-					ProjectGUID:    projectGUID,
-					SolutionConfig: solutionConfig,
-					ProjectConfig:  projectConfig,
-					ShouldBuild:    shouldBuild,
+					ProjectGUID:           projectGUID,
+					SolutionConfiguration: solutionConfiguration,
+					SolutionPlatform:      solutionPlatform,
+					ProjectConfiguration:  projectConfiguration,
+					ProjectPlatform:       projectPlatform,
+					ShouldBuild:           shouldBuild,
 				})
 			}
 		}
@@ -164,28 +476,40 @@ func (sln *Sln) parseConfigurationMappings(content string) error {
 	return nil
 }
 
-// 根据解决方案Configuration查找项目对应的Configuration
-func (sln *Sln) GetProjectConfig(projectPath, solutionConfig string) (string, error) {
+// splitConfigPlatform 将"Debug|x64"形式的字符串拆分为Configuration和Platform两部分。
+// 如果输入中不包含"|"（理论上不应发生，但做个兜底），Platform返回空字符串
+func splitConfigPlatform(s string) (configuration, platform string) {
+	parts := strings.SplitN(s, "|", 2)
+	configuration = parts[0]
+	if len(parts) == 2 {
+		platform = parts[1]
+	}
+	return configuration, platform
+}
+
+// 根据解决方案Configuration+Platform查找项目对应的Configuration+Platform
+func (sln *Sln) GetProjectConfig(projectPath, solutionConfiguration, solutionPlatform string) (string, string, error) {
 	// 获取项目的GUID
 	projectGUID, exists := sln.ProjectGUIDs[projectPath]
 	if !exists {
-		return "", fmt.Errorf("项目 %s 未在解决方案中找到", projectPath)
+		return "", "", fmt.Errorf("项目 %s 未在解决方案中找到", projectPath)
 	}
 
 	// 查找Configuration映射
 	for _, mapping := range sln.ConfigMappings {
-		if mapping.ProjectGUID == projectGUID && mapping.SolutionConfig == solutionConfig {
-			// log.Printf("%+v\n", mapping.ProjectConfig)
-			return mapping.ProjectConfig, nil
+		if mapping.ProjectGUID == projectGUID &&
+			mapping.SolutionConfiguration == solutionConfiguration &&
+			mapping.SolutionPlatform == solutionPlatform {
+			return mapping.ProjectConfiguration, mapping.ProjectPlatform, nil
 		}
 	}
 
-	// 如果没有找到映射，返回默认值（与解决方案Configuration相同）
-	return solutionConfig, nil
+	// 如果没有找到映射，返回默认值（与解决方案Configuration+Platform相同）
+	return solutionConfiguration, solutionPlatform, nil
 }
 
-// 根据项目对象查找对应的Configuration
-func (sln *Sln) GetProjectConfigByProject(pro *Project, solutionConfig string) (string, error) {
+// 根据项目对象查找对应的Configuration+Platform
+func (sln *Sln) GetProjectConfigByProject(pro *Project, solutionConfiguration, solutionPlatform string) (string, string, error) {
 	// 查找该项目在ProjectGUIDs中的路径
 	var projectPath string
 	for path := range sln.ProjectGUIDs {
@@ -198,10 +522,10 @@ func (sln *Sln) GetProjectConfigByProject(pro *Project, solutionConfig string) (
 	}
 
 	if projectPath == "" {
-		return solutionConfig, nil // 如果找不到，返回默认值
+		return solutionConfiguration, solutionPlatform, nil // 如果找不到，返回默认值
 	}
 
-	return sln.GetProjectConfig(projectPath, solutionConfig)
+	return sln.GetProjectConfig(projectPath, solutionConfiguration, solutionPlatform)
 }
 
 // 弃用原来的findAllProject函数，因为我们现在从parseSolutionFile中解析项目
@@ -210,47 +534,208 @@ func findAllProject(path string) ([]string, error) {
 	return []string{}, errors.New("此函数已弃用，请使用新的解析方法")
 }
 
-func (sln *Sln) CompileCommandsJson(conf string) ([]CompileCommand, error) {
-	var cmdList []CompileCommand
+// CompileCommandsJsonOptions控制CompileCommandsJson对不参与当前解决方案Configuration
+// 构建的项目的处理方式
+type CompileCommandsJsonOptions struct {
+	IncludeNonBuilding bool // true时连没有Build.0条目（即被排除在构建之外）的项目也一起导出
+}
 
-	for _, pro := range sln.ProjectList {
-		var item CompileCommand
+func (sln *Sln) CompileCommandsJson(conf string, platform string) ([]CompileCommand, error) {
+	return sln.CompileCommandsJsonWithOptions(conf, platform, CompileCommandsJsonOptions{})
+}
 
-		// 获取项目对应的Configuration
-		projectConfig, err := sln.GetProjectConfigByProject(&pro, conf)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "警告: %v, 使用默认Configuration: %s\n", err, conf)
-			projectConfig = conf
-		}
+// CompileCommandsJsonWithOptions与CompileCommandsJson相同，但允许通过opts保留
+// 未参与当前Configuration构建的项目
+func (sln *Sln) CompileCommandsJsonWithOptions(conf string, platform string, opts CompileCommandsJsonOptions) ([]CompileCommand, error) {
+	if err := sln.validateSolutionConfig(conf, platform); err != nil {
+		return nil, err
+	}
 
-		for _, f := range pro.FindSourceFiles() {
-			item.Dir = pro.ProjectDir
-			item.File = f
+	// 优先按构建依赖顺序导出，让消费compile_commands.json的下游工具（如增量索引器）
+	// 能先看到被依赖的项目；存在循环依赖时退回ProjectList原有顺序，不阻塞整体导出
+	var projects []*Project
+	if order, err := sln.BuildOrder(); err == nil {
+		projects = order
+	} else {
+		projects = make([]*Project, 0, len(sln.ProjectList))
+		for i := range sln.ProjectList {
+			projects = append(projects, &sln.ProjectList[i])
+		}
+	}
 
-			inc, def, err := pro.FindConfig(projectConfig)
-			if err != nil {
-				return cmdList, err
+	if !opts.IncludeNonBuilding {
+		building := projects[:0]
+		for _, pro := range projects {
+			if sln.shouldBuildProject(pro, conf, platform) {
+				building = append(building, pro)
 			}
-			willReplaceEnv := map[string]string{
-				"$(SolutionDir)": sln.SolutionDir,
-			}
-			for k, v := range willReplaceEnv {
-				inc = strings.Replace(inc, k, v, -1)
+		}
+		projects = building
+	}
+
+	// 每个项目独立生成自己的CompileCommand列表，项目之间没有并发写冲突，
+	// 因此可以并发执行；结果按projects的顺序聚合，保证输出确定性
+	type projectResult struct {
+		cmds []CompileCommand
+		err  error
+	}
+
+	results := make([]projectResult, len(projects))
+
+	concurrency := runtime.NumCPU()
+	if concurrency > len(projects) {
+		concurrency = len(projects)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				cmds, err := sln.compileCommandsForProject(projects[i], conf, platform)
+				results[i] = projectResult{cmds: cmds, err: err}
 			}
-			def = RemoveBadDefinition(def)
-			def = preappend(def, "-D")
+		}()
+	}
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-			inc = RemoveBadInclude(inc)
-			inc = preappend(inc, "-I")
+	var cmdList []CompileCommand
+	for _, r := range results {
+		if r.err != nil {
+			return nil, r.err
+		}
+		cmdList = append(cmdList, r.cmds...)
+	}
+	return cmdList, nil
+}
 
-			cmd := "clang-cl.exe " + def + " " + inc + " -c " + f
-			item.Cmd = cmd
+// shouldBuildProject报告pro在(solutionConfiguration, solutionPlatform)下是否会被构建，
+// 即.sln中是否存在对应的{GUID}.Config|Platform.Build.0条目。
+// 找不到项目GUID或找不到Configuration映射时保守地认为需要构建，与历史行为保持一致
+func (sln *Sln) shouldBuildProject(pro *Project, solutionConfiguration, solutionPlatform string) bool {
+	guid, ok := sln.guidForProjectPath(pro.ProjectPath)
+	if !ok {
+		return true
+	}
 
-			cmdList = append(cmdList, item)
+	for _, m := range sln.ConfigMappings {
+		if m.ProjectGUID == guid &&
+			m.SolutionConfiguration == solutionConfiguration &&
+			m.SolutionPlatform == solutionPlatform {
+			return m.ShouldBuild
 		}
+	}
+
+	return true
+}
 
+// compileCommandsForProject为单个项目生成它全部源文件的CompileCommand。
+// FindConfig针对该项目+Configuration+Platform只调用一次（经sln.cache去重），
+// 而不是像之前那样对每个源文件都重新解析一遍
+func (sln *Sln) compileCommandsForProject(pro *Project, conf, platform string) ([]CompileCommand, error) {
+	// 获取项目对应的Configuration+Platform，两者都可能与解决方案不同
+	projectConfig, projectPlatform, err := sln.GetProjectConfigByProject(pro, conf, platform)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "警告: %v, 使用默认Configuration: %s|%s\n", err, conf, platform)
+		projectConfig = conf
+		projectPlatform = platform
 	}
-	return cmdList, nil
+
+	macros := NewMacroExpander(sln, pro, projectConfig, projectPlatform)
+
+	inc, def, opts, err := sln.findConfigCached(pro, projectConfig, projectPlatform, macros)
+	if err != nil {
+		return nil, err
+	}
+
+	def = RemoveBadDefinition(def)
+	def = preappend(def, "-D")
+
+	inc = RemoveBadInclude(inc)
+	inc = preappend(inc, "-I")
+
+	opts = RemoveBadAdditionalOptions(opts)
+
+	arch := archFlag(projectPlatform)
+
+	var cmds []CompileCommand
+	for _, f := range pro.FindSourceFiles() {
+		cmds = append(cmds, CompileCommand{
+			Dir:  pro.ProjectDir,
+			File: f,
+			Cmd:  strings.TrimSpace("clang-cl.exe "+arch+" "+def+" "+inc+" "+opts) + " -c " + f,
+		})
+	}
+
+	return cmds, nil
+}
+
+// findConfigCached包装pro.FindConfig，以"ProjectPath|Configuration|Platform"为键缓存结果，
+// 避免同一项目配置在并发生成或多次Validate调用中被重复解析。
+// macros只在cache未命中时用到，由调用方构造好传入，这样缓存本身不必关心宏展开的细节
+func (sln *Sln) findConfigCached(pro *Project, configuration, platform string, macros *MacroExpander) (string, string, string, error) {
+	key := pro.ProjectPath + "|" + configuration + "|" + platform
+
+	sln.cache.mu.Lock()
+	if c, ok := sln.cache.entries[key]; ok {
+		sln.cache.mu.Unlock()
+		return c.inc, c.def, c.opts, nil
+	}
+	sln.cache.mu.Unlock()
+
+	inc, def, opts, err := pro.FindConfig(configuration+"|"+platform, macros)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	sln.cache.mu.Lock()
+	sln.cache.entries[key] = cachedConfig{inc: inc, def: def, opts: opts}
+	sln.cache.mu.Unlock()
+
+	return inc, def, opts, nil
+}
+
+// archFlag 根据项目的Platform返回clang-cl的架构提示参数，
+// 未知Platform（如"Any CPU"）不附加任何参数，交给clang-cl使用默认目标
+func archFlag(platform string) string {
+	switch strings.ToLower(platform) {
+	case "x64":
+		return "-m64"
+	case "win32", "x86":
+		return "-m32"
+	default:
+		return ""
+	}
+}
+
+// validateSolutionConfig 校验conf|platform是否是解决方案中存在的Configuration|Platform组合，
+// 如果解析不到SolutionConfigurationPlatforms节（说明.sln没有提供该信息）则跳过校验
+func (sln *Sln) validateSolutionConfig(conf, platform string) error {
+	if len(sln.solutionConfigs) == 0 {
+		return nil
+	}
+
+	for _, c := range sln.solutionConfigs {
+		if c.Configuration == conf && c.Platform == platform {
+			return nil
+		}
+	}
+
+	valid := make([]string, 0, len(sln.solutionConfigs))
+	for _, c := range sln.solutionConfigs {
+		valid = append(valid, c.String())
+	}
+
+	return fmt.Errorf("无效的Configuration|Platform \"%s|%s\"，可选值为: %s", conf, platform, strings.Join(valid, ", "))
 }
 
 func preappend(sepedString string, append string) string {
@@ -275,6 +760,13 @@ func RemoveBadInclude(inc string) string {
 	return removeInherited(inc, "%(AdditionalIncludeDirectories)")
 }
 
+// RemoveBadAdditionalOptions去掉AdditionalOptions里的%(AdditionalOptions)占位符。
+// 与RemoveBadDefinition/RemoveBadInclude不同，AdditionalOptions不是分号分隔的列表，
+// 而是一整段命令行参数，所以不能按removeInherited的分号拆分逻辑处理
+func RemoveBadAdditionalOptions(opts string) string {
+	return strings.TrimSpace(strings.ReplaceAll(opts, "%(AdditionalOptions)", ""))
+}
+
 // removeInherited按分号拆分sepedString，去掉空项和placeholder项
 func removeInherited(sepedString, placeholder string) string {
 	items := strings.Split(sepedString, ";")
@@ -0,0 +1,30 @@
+package sln
+
+import "testing"
+
+// TestParseProjectDependencies_SectionBoundary覆盖了一个曾经的bug：
+// 非贪婪匹配在截取Project(...)...EndProject块时，被更早出现在
+// ProjectSection(ProjectDependencies)...EndProjectSection里的字面子串
+// "EndProject"提前截断，导致section永远取不到ProjectSection，
+// ProjectSection(ProjectDependencies)的依赖被静默丢弃
+func TestParseProjectDependencies_SectionBoundary(t *testing.T) {
+	content := `
+Project("{8BC9CEB8-8B4A-11D0-8D11-00A0C91BC942}") = "foo", "foo\foo.vcxproj", "{11111111-1111-1111-1111-111111111111}"
+	ProjectSection(ProjectDependencies) = postProject
+		{22222222-2222-2222-2222-222222222222} = {22222222-2222-2222-2222-222222222222}
+	EndProjectSection
+EndProject
+Project("{8BC9CEB8-8B4A-11D0-8D11-00A0C91BC942}") = "bar", "bar\bar.vcxproj", "{22222222-2222-2222-2222-222222222222}"
+EndProject
+`
+
+	var sln Sln
+	if err := sln.parseProjectDependencies(content); err != nil {
+		t.Fatalf("parseProjectDependencies返回错误: %v", err)
+	}
+
+	deps := sln.Dependencies["11111111-1111-1111-1111-111111111111"]
+	if len(deps) != 1 || deps[0] != "22222222-2222-2222-2222-222222222222" {
+		t.Fatalf("期望foo依赖bar，实际Dependencies = %v", sln.Dependencies)
+	}
+}
@@ -0,0 +1,80 @@
+package sln
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ValidationReport 汇总Validate对解决方案做的静态检查结果，
+// 供`vs_export validate`子命令直接打印
+type ValidationReport struct {
+	MissingProjectFiles []string // 在.sln中引用、但磁盘上找不到的项目文件
+	OrphanedMappings    []string // ConfigMappings中引用了.sln里未声明的项目GUID
+	UnresolvedMacros    []string // 展开include/宏定义后仍残留$(...)引用，或展开过程中出现宏循环引用
+	ProjectConfigErrors []string // FindConfig失败（如项目文件里找不到对应的Configuration|Platform），与宏无关
+}
+
+// OK 报告中没有任何问题时返回true
+func (r ValidationReport) OK() bool {
+	return len(r.MissingProjectFiles) == 0 && len(r.OrphanedMappings) == 0 &&
+		len(r.UnresolvedMacros) == 0 && len(r.ProjectConfigErrors) == 0
+}
+
+// Validate 对解决方案做一遍静态检查：缺失的项目文件、孤立的Configuration映射、
+// 以及展开后仍无法解析的宏引用。只做只读检查，不会修改sln本身
+func (sln *Sln) Validate() ValidationReport {
+	var report ValidationReport
+
+	for path := range sln.ProjectGUIDs {
+		absPath := filepath.Join(sln.SolutionDir, path)
+		if _, err := os.Stat(absPath); err != nil {
+			report.MissingProjectFiles = append(report.MissingProjectFiles, path)
+		}
+	}
+
+	knownGUIDs := make(map[string]bool, len(sln.ProjectGUIDs))
+	for _, guid := range sln.ProjectGUIDs {
+		knownGUIDs[guid] = true
+	}
+	for _, m := range sln.ConfigMappings {
+		if !knownGUIDs[m.ProjectGUID] {
+			report.OrphanedMappings = append(report.OrphanedMappings,
+				fmt.Sprintf("{%s}.%s|%s", m.ProjectGUID, m.SolutionConfiguration, m.SolutionPlatform))
+		}
+	}
+
+	for i := range sln.ProjectList {
+		pro := &sln.ProjectList[i]
+		for _, sc := range sln.solutionConfigs {
+			projectConfig, projectPlatform, err := sln.GetProjectConfigByProject(pro, sc.Configuration, sc.Platform)
+			if err != nil {
+				continue
+			}
+
+			macros := NewMacroExpander(sln, pro, projectConfig, projectPlatform)
+			inc, def, opts, err := sln.findConfigCached(pro, projectConfig, projectPlatform, macros)
+			if err != nil {
+				if errors.Is(err, ErrMacroCycle) {
+					report.UnresolvedMacros = append(report.UnresolvedMacros,
+						fmt.Sprintf("%s (%s): %v", pro.ProjectPath, sc.String(), err))
+				} else {
+					report.ProjectConfigErrors = append(report.ProjectConfigErrors,
+						fmt.Sprintf("%s (%s): %v", pro.ProjectPath, sc.String(), err))
+				}
+				continue
+			}
+
+			for _, expanded := range []string{inc, def, opts} {
+				if strings.Contains(expanded, "$(") {
+					report.UnresolvedMacros = append(report.UnresolvedMacros,
+						fmt.Sprintf("%s (%s): %s", pro.ProjectPath, sc.String(), expanded))
+				}
+			}
+		}
+	}
+
+	return report
+}